@@ -0,0 +1,194 @@
+// Package agent drives the tool-call loop on top of a provider.Provider: it
+// keeps calling the model, executes any tool calls it returns, feeds the
+// results back, and repeats until the model is done. The provider layer
+// itself stays stateless and only ever produces a single tool_calls turn.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/provider"
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/tools"
+	"https://github.com/0xJohnnes/cli4ifx/internal/logging"
+	"https://github.com/0xJohnnes/cli4ifx/internal/message"
+)
+
+// defaultMaxIterations bounds the number of provider round-trips a single
+// Run can make, guarding against a model that never stops requesting tools.
+const defaultMaxIterations = 25
+
+// ErrMaxIterations is returned when Run hits the iteration guard without the
+// provider returning a non-tool finish reason.
+var ErrMaxIterations = errors.New("agent: max iterations reached")
+
+// ToolApproval is consulted before a tool call is executed. Returning false
+// skips the call and reports it to the model as a declined tool result; a
+// non-nil error aborts the run.
+type ToolApproval func(ctx context.Context, call message.ToolCall) (bool, error)
+
+// Agent wraps a provider.Provider with a tool registry and drives the full
+// request/execute/respond loop that callers previously had to do by hand.
+type Agent struct {
+	provider      provider.Provider
+	tools         []tools.BaseTool
+	approval      ToolApproval
+	maxIterations int
+}
+
+type Option func(*Agent)
+
+// WithToolApproval installs a hook the agent consults before running each
+// tool call, e.g. so the TUI can prompt the user first.
+func WithToolApproval(fn ToolApproval) Option {
+	return func(a *Agent) {
+		a.approval = fn
+	}
+}
+
+// WithMaxIterations overrides the default tool-loop iteration guard.
+func WithMaxIterations(n int) Option {
+	return func(a *Agent) {
+		if n > 0 {
+			a.maxIterations = n
+		}
+	}
+}
+
+func New(p provider.Provider, toolset []tools.BaseTool, opts ...Option) *Agent {
+	a := &Agent{
+		provider:      p,
+		tools:         toolset,
+		maxIterations: defaultMaxIterations,
+	}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+func (a *Agent) findTool(name string) tools.BaseTool {
+	for _, t := range a.tools {
+		if t.Name() == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Run sends messages to the provider and, as long as it keeps asking for
+// tools, executes them and re-invokes the provider with the results
+// appended. It returns the first response with a non-tool_calls finish
+// reason, along with the full message history including every tool
+// round-trip.
+func (a *Agent) Run(ctx context.Context, messages []message.Message) ([]message.Message, *provider.ProviderResponse, error) {
+	for iteration := 0; ; iteration++ {
+		if iteration >= a.maxIterations {
+			return messages, nil, ErrMaxIterations
+		}
+
+		resp, err := a.provider.SendMessages(ctx, messages, a.tools)
+		if err != nil {
+			return messages, nil, err
+		}
+
+		if resp.FinishReason != message.FinishReasonToolCalls || len(resp.ToolCalls) == 0 {
+			return messages, resp, nil
+		}
+
+		results := make([]message.ToolResult, 0, len(resp.ToolCalls))
+		for _, call := range resp.ToolCalls {
+			result, err := a.execute(ctx, call)
+			if err != nil {
+				return messages, nil, err
+			}
+			results = append(results, result)
+		}
+
+		messages = append(messages, message.NewAssistantMessage(resp), message.NewToolResultMessage(results...))
+	}
+}
+
+// RunStream is the streaming counterpart of Run: it forwards every event
+// from the underlying provider and additionally emits EventIterationStart
+// before each provider call and EventToolResult once a tool call has been
+// executed, so the caller can render progress across the whole loop instead
+// of just a single turn.
+func (a *Agent) RunStream(ctx context.Context, messages []message.Message) <-chan provider.ProviderEvent {
+	out := make(chan provider.ProviderEvent)
+
+	go func() {
+		defer close(out)
+
+		for iteration := 0; ; iteration++ {
+			if iteration >= a.maxIterations {
+				out <- provider.ProviderEvent{Type: provider.EventError, Error: ErrMaxIterations}
+				return
+			}
+
+			out <- provider.ProviderEvent{Type: provider.EventIterationStart, Iteration: iteration}
+
+			var resp *provider.ProviderResponse
+			for event := range a.provider.StreamResponse(ctx, messages, a.tools) {
+				if event.Type == provider.EventError {
+					out <- event
+					return
+				}
+				if event.Type == provider.EventComplete {
+					resp = event.Response
+				}
+				out <- event
+			}
+
+			if resp == nil {
+				out <- provider.ProviderEvent{Type: provider.EventError, Error: errors.New("agent: stream completed without a final response")}
+				return
+			}
+
+			if resp.FinishReason != message.FinishReasonToolCalls || len(resp.ToolCalls) == 0 {
+				return
+			}
+
+			results := make([]message.ToolResult, 0, len(resp.ToolCalls))
+			for _, call := range resp.ToolCalls {
+				result, err := a.execute(ctx, call)
+				if err != nil {
+					out <- provider.ProviderEvent{Type: provider.EventError, Error: err}
+					return
+				}
+				out <- provider.ProviderEvent{Type: provider.EventToolResult, ToolResult: &result}
+				results = append(results, result)
+			}
+
+			messages = append(messages, message.NewAssistantMessage(resp), message.NewToolResultMessage(results...))
+		}
+	}()
+
+	return out
+}
+
+func (a *Agent) execute(ctx context.Context, call message.ToolCall) (message.ToolResult, error) {
+	if a.approval != nil {
+		approved, err := a.approval(ctx, call)
+		if err != nil {
+			return message.ToolResult{}, err
+		}
+		if !approved {
+			return message.ToolResult{CallID: call.ID, Content: "tool call declined by user", IsError: true}, nil
+		}
+	}
+
+	tool := a.findTool(call.Name)
+	if tool == nil {
+		return message.ToolResult{CallID: call.ID, Content: fmt.Sprintf("unknown tool: %s", call.Name), IsError: true}, nil
+	}
+
+	content, err := tool.Run(ctx, call)
+	if err != nil {
+		logging.Error("Tool call failed", "tool", call.Name, "error", err)
+		return message.ToolResult{CallID: call.ID, Content: err.Error(), IsError: true}, nil
+	}
+
+	return message.ToolResult{CallID: call.ID, Content: content}, nil
+}
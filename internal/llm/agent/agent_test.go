@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/models"
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/provider"
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/tools"
+	"https://github.com/0xJohnnes/cli4ifx/internal/message"
+)
+
+// TestRun_TwoToolCallRounds scripts a provider that asks for a tool call,
+// then answers once it sees the result, and asserts that the second
+// SendMessages call receives a well-formed history: the assistant's own
+// tool_calls turn must precede the tool-result message, or a real
+// OpenAI-compatible endpoint would reject it.
+func TestRun_TwoToolCallRounds(t *testing.T) {
+	call := message.ToolCall{ID: "call_1", Name: "echo"}
+
+	var secondRoundMessages []message.Message
+
+	p, err := provider.NewProvider(models.ProviderMock,
+		provider.WithMockOptions(provider.WithMockScript(
+			provider.MockResponse{
+				Response: &provider.ProviderResponse{
+					ToolCalls:    []message.ToolCall{call},
+					FinishReason: message.FinishReasonToolCalls,
+				},
+			},
+			provider.MockResponse{
+				OnCall: func(messages []message.Message, _ []tools.BaseTool) {
+					secondRoundMessages = messages
+				},
+				Response: &provider.ProviderResponse{
+					Content:      "done",
+					FinishReason: message.FinishReasonStop,
+				},
+			},
+		)),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	a := New(p, nil)
+	history, resp, err := a.Run(context.Background(), []message.Message{message.NewUserMessage("hi")})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if resp.Content != "done" {
+		t.Fatalf("final response = %+v, want content %q", resp, "done")
+	}
+
+	if len(secondRoundMessages) < 3 {
+		t.Fatalf("second round saw %d messages, want at least user+assistant+tool_result", len(secondRoundMessages))
+	}
+
+	assistantTurn := secondRoundMessages[len(secondRoundMessages)-2]
+	toolResultTurn := secondRoundMessages[len(secondRoundMessages)-1]
+
+	if assistantTurn.Role() != message.RoleAssistant {
+		t.Errorf("second-to-last message role = %v, want %v (the assistant's tool_calls turn must precede the tool result)", assistantTurn.Role(), message.RoleAssistant)
+	}
+	if toolResultTurn.Role() != message.RoleTool {
+		t.Errorf("last message role = %v, want %v", toolResultTurn.Role(), message.RoleTool)
+	}
+
+	if len(history) != len(secondRoundMessages) {
+		t.Errorf("Run returned %d messages, want the same history the provider last saw (%d)", len(history), len(secondRoundMessages))
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "maps"
+import (
+	"maps"
+	"sync"
+)
 
 type (
 	ModelID       string
@@ -23,21 +26,61 @@ type Model struct {
 }
 
 const (
-	ProviderOpenAI    ModelProvider = "openai"
-	ProviderInfineon  ModelProvider = "infineon"
+	ProviderOpenAI   ModelProvider = "openai"
+	ProviderInfineon ModelProvider = "infineon"
+	// ProviderLocal covers any OpenAI-compatible self-hosted endpoint
+	// (Ollama, LocalAI, llama.cpp, vLLM, LM Studio, ...).
+	ProviderLocal ModelProvider = "local"
 	// ForTests
-	ProviderMock      ModelProvider = "__mock"
+	ProviderMock ModelProvider = "__mock"
 )
 
 // Providers in order of popularity
 var ProviderPopularity = map[ModelProvider]int{
 	ProviderInfineon: 1,
 	ProviderOpenAI:   2,
+	ProviderLocal:    3,
 }
 
+// supportedModelsMu guards SupportedModels. It only ever sees writes after
+// init (e.g. the local provider registering models it discovered at
+// runtime via /v1/models), but since that happens from a background
+// goroutine while the rest of the app may be reading the map for a model
+// picker, every access has to go through it.
+var supportedModelsMu sync.RWMutex
+
 var SupportedModels = map[ModelID]Model{}
 
 func init() {
 	maps.Copy(SupportedModels, InfineonModels)
 	maps.Copy(SupportedModels, OpenAIModels)
 }
+
+// RegisterModel safely adds or updates a model in SupportedModels. Anything
+// that discovers models at runtime (rather than declaring them as a
+// package-level map literal like InfineonModels) must use this instead of
+// writing to SupportedModels directly.
+func RegisterModel(m Model) {
+	supportedModelsMu.Lock()
+	defer supportedModelsMu.Unlock()
+	SupportedModels[m.ID] = m
+}
+
+// GetModel safely reads a single model by ID.
+func GetModel(id ModelID) (Model, bool) {
+	supportedModelsMu.RLock()
+	defer supportedModelsMu.RUnlock()
+	m, ok := SupportedModels[id]
+	return m, ok
+}
+
+// ListModels returns a point-in-time snapshot of SupportedModels, safe to
+// range over even while another goroutine is registering new models (e.g.
+// for a model picker UI).
+func ListModels() map[ModelID]Model {
+	supportedModelsMu.RLock()
+	defer supportedModelsMu.RUnlock()
+	out := make(map[ModelID]Model, len(SupportedModels))
+	maps.Copy(out, SupportedModels)
+	return out
+}
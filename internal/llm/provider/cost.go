@@ -0,0 +1,31 @@
+package provider
+
+import "https://github.com/0xJohnnes/cli4ifx/internal/llm/models"
+
+// ProviderStats is the cumulative usage and spend a provider has
+// accumulated across every call made through it, exposed via Provider.Stats
+// for TUI status bars and budget guards.
+type ProviderStats struct {
+	RequestCount int
+	TotalUsage   TokenUsage
+	TotalCost    float64
+}
+
+// CostOf computes the dollar cost of a single TokenUsage under a model's
+// per-million-token pricing, accounting for cache-read and cache-write
+// tokens separately from regular input/output tokens.
+func CostOf(model models.Model, u TokenUsage) float64 {
+	const perMillion = 1_000_000
+
+	regularInput := u.InputTokens - u.CacheCreationTokens - u.CacheReadTokens
+	if regularInput < 0 {
+		regularInput = 0
+	}
+
+	cost := float64(regularInput) / perMillion * model.CostPer1MIn
+	cost += float64(u.OutputTokens) / perMillion * model.CostPer1MOut
+	cost += float64(u.CacheCreationTokens) / perMillion * model.CostPer1MIn
+	cost += float64(u.CacheReadTokens) / perMillion * model.CostPer1MInCached
+
+	return cost
+}
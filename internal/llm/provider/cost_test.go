@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/models"
+)
+
+func TestCostOf(t *testing.T) {
+	model := models.Model{
+		CostPer1MIn:        2.50,
+		CostPer1MOut:       10.00,
+		CostPer1MInCached:  1.25,
+		CostPer1MOutCached: 0.0,
+	}
+
+	tests := []struct {
+		name string
+		u    TokenUsage
+		want float64
+	}{
+		{
+			name: "regular input and output only",
+			u:    TokenUsage{InputTokens: 1_000_000, OutputTokens: 1_000_000},
+			want: 12.50,
+		},
+		{
+			name: "cache read is cheaper than regular input",
+			u:    TokenUsage{InputTokens: 1_000_000, CacheReadTokens: 1_000_000},
+			want: 1.25,
+		},
+		{
+			name: "cache creation bills at the regular input rate",
+			u:    TokenUsage{InputTokens: 1_000_000, CacheCreationTokens: 1_000_000},
+			want: 2.50,
+		},
+		{
+			name: "cache tokens are excluded from the regular-input bucket",
+			u:    TokenUsage{InputTokens: 1_000_000, CacheCreationTokens: 400_000, CacheReadTokens: 600_000},
+			want: 1.75, // 400k cache-write @ $2.50/M + 600k cache-read @ $1.25/M, 0 regular input
+		},
+		{
+			name: "zero usage costs nothing",
+			u:    TokenUsage{},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CostOf(model, tt.u); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CostOf(%+v) = %v, want %v", tt.u, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStats_AccumulatesAcrossCalls exercises Provider.Stats end to end: each
+// call's usage and cost should add onto the running total rather than
+// replacing it, independent of how many SendMessages calls a caller makes.
+func TestStats_AccumulatesAcrossCalls(t *testing.T) {
+	model := models.Model{CostPer1MIn: 2.50, CostPer1MOut: 10.00}
+
+	p, err := NewProvider(models.ProviderMock,
+		WithModel(model),
+		WithMockOptions(WithMockScript(
+			MockResponse{Response: &ProviderResponse{Content: "a", Usage: TokenUsage{InputTokens: 1_000_000, OutputTokens: 500_000}}},
+			MockResponse{Response: &ProviderResponse{Content: "b", Usage: TokenUsage{InputTokens: 2_000_000, OutputTokens: 500_000}}},
+		)),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.SendMessages(context.Background(), nil, nil); err != nil {
+			t.Fatalf("SendMessages %d: %v", i, err)
+		}
+	}
+
+	stats := p.Stats()
+	if stats.RequestCount != 2 {
+		t.Errorf("RequestCount = %d, want 2", stats.RequestCount)
+	}
+
+	wantUsage := TokenUsage{InputTokens: 3_000_000, OutputTokens: 1_000_000}
+	if stats.TotalUsage != wantUsage {
+		t.Errorf("TotalUsage = %+v, want %+v", stats.TotalUsage, wantUsage)
+	}
+
+	wantCost := CostOf(model, TokenUsage{InputTokens: 1_000_000, OutputTokens: 500_000}) +
+		CostOf(model, TokenUsage{InputTokens: 2_000_000, OutputTokens: 500_000})
+	if math.Abs(stats.TotalCost-wantCost) > 1e-9 {
+		t.Errorf("TotalCost = %v, want %v", stats.TotalCost, wantCost)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/openai/openai-go"
@@ -30,6 +31,7 @@ type infineonClient struct {
 	providerOptions providerClientOptions
 	options         infineonOptions
 	client          openai.Client
+	retryPolicy     RetryPolicy
 }
 
 type InfineonClient ProviderClient
@@ -56,11 +58,17 @@ func newInfineonClient(opts providerClientOptions) InfineonClient {
 		}
 	}
 
+	retryPolicy := opts.retryPolicy
+	if retryPolicy == nil {
+		retryPolicy = &ExponentialBackoff{Base: time.Second, Max: 60 * time.Second, Jitter: true}
+	}
+
 	client := openai.NewClient(openaiClientOptions...)
 	return &infineonClient{
 		providerOptions: opts,
 		options:         infineonOpts,
 		client:          client,
+		retryPolicy:     retryPolicy,
 	}
 }
 
@@ -173,7 +181,7 @@ func (i *infineonClient) finishReason(reason string) message.FinishReason {
 	}
 }
 
-func (i *infineonClient) preparedParams(messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolParam) openai.ChatCompletionNewParams {
+func (i *infineonClient) preparedParams(messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolParam) (openai.ChatCompletionNewParams, error) {
 	params := openai.ChatCompletionNewParams{
 		Messages: messages,
 		Model:    i.providerOptions.model.APIModel,
@@ -187,25 +195,43 @@ func (i *infineonClient) preparedParams(messages []openai.ChatCompletionMessageP
 		params.MaxTokens = openai.Int(int(i.providerOptions.maxTokens))
 	}
 
-	return params
+	if err := applyResponseFormat(&params, i.providerOptions.responseFormat); err != nil {
+		return params, err
+	}
+
+	if i.providerOptions.model.CanReason && i.providerOptions.reasoningEffort != "" {
+		params.ReasoningEffort = shared.ReasoningEffort(i.providerOptions.reasoningEffort)
+	}
+
+	return params, nil
 }
 
 func (i *infineonClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (response *ProviderResponse, err error) {
 	openaiMessages := i.convertMessages(messages)
 	openaiTools := i.convertTools(tools)
-	params := i.preparedParams(openaiMessages, openaiTools)
+	params, err := i.preparedParams(openaiMessages, openaiTools)
+	if err != nil {
+		return nil, err
+	}
 
 	var completion openai.ChatCompletion
 	for attempts := 0; attempts < maxRetries; attempts++ {
-		completion, err = i.client.CreateChatCompletion(ctx, params)
+		if paceErr := pace(ctx, i.retryPolicy); paceErr != nil {
+			return nil, paceErr
+		}
+
+		var httpResp *http.Response
+		completion, err = i.client.CreateChatCompletion(ctx, params, option.WithResponseInto(&httpResp))
+		recordHeaders(i.retryPolicy, responseHeader(httpResp, err))
 		if err == nil {
 			break
 		}
 
-		shouldRetry, sleepDuration, retryErr := i.shouldRetry(attempts, err)
-		if retryErr != nil {
-			return nil, retryErr
+		if attempts >= maxRetries-1 {
+			return nil, fmt.Errorf("max retries reached: %w", err)
 		}
+
+		shouldRetry, sleepDuration := i.retryPolicy.ShouldRetry(attempts, err)
 		if !shouldRetry {
 			return nil, err
 		}
@@ -228,8 +254,14 @@ func (i *infineonClient) send(ctx context.Context, messages []message.Message, t
 		content = *choice.Message.Content
 	}
 
+	thinking := ""
+	if i.providerOptions.model.CanReason {
+		thinking = choice.Message.ReasoningContent
+	}
+
 	return &ProviderResponse{
 		Content:      content,
+		Thinking:     thinking,
 		ToolCalls:    i.toolCalls(completion),
 		Usage:        i.usage(completion),
 		FinishReason: i.finishReason(choice.FinishReason),
@@ -239,192 +271,221 @@ func (i *infineonClient) send(ctx context.Context, messages []message.Message, t
 func (i *infineonClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
 	openaiMessages := i.convertMessages(messages)
 	openaiTools := i.convertTools(tools)
-	params := i.preparedParams(openaiMessages, openaiTools)
+	params, err := i.preparedParams(openaiMessages, openaiTools)
+	if err != nil {
+		return errorEventChan(err)
+	}
 	params.Stream = openai.Bool(true)
+	// Ask for a final usage-bearing chunk; without this the stream never
+	// reports token counts and EventComplete always carries a zero usage.
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.Bool(true),
+	}
 
 	eventChan := make(chan ProviderEvent)
 
 	go func() {
 		defer close(eventChan)
 
-		var stream openai.ChatCompletionStream
-		var err error
+		state := &streamState{}
+		contentStarted := false
+		// Index into params.Messages of the assistant message carrying the
+		// partial content from a previous attempt, or -1 if none has been
+		// added yet. Each retry overwrites this single message with the
+		// latest accumulated content instead of appending a new one, so a
+		// stream that fails N times doesn't leave N duplicate partial
+		// assistant turns in the conversation sent to the model.
+		resumeMsgIdx := -1
 
 		for attempts := 0; attempts < maxRetries; attempts++ {
-			stream, err = i.client.CreateChatCompletionStream(ctx, params)
-			if err == nil {
-				break
+			if paceErr := pace(ctx, i.retryPolicy); paceErr != nil {
+				eventChan <- ProviderEvent{Type: EventError, Error: paceErr}
+				return
 			}
 
-			shouldRetry, sleepDuration, retryErr := i.shouldRetry(attempts, err)
-			if retryErr != nil {
-				eventChan <- ProviderEvent{
-					Type:  EventError,
-					Error: retryErr,
+			var httpResp *http.Response
+			stream, err := i.client.CreateChatCompletionStream(ctx, params, option.WithResponseInto(&httpResp))
+			recordHeaders(i.retryPolicy, responseHeader(httpResp, err))
+			if err == nil {
+				if !contentStarted {
+					eventChan <- ProviderEvent{Type: EventContentStart}
+					contentStarted = true
 				}
+
+				err = i.recvStream(stream, eventChan, state)
+				stream.Close()
+				if err == nil {
+					i.finishStream(eventChan, state)
+					return
+				}
+			}
+
+			if attempts >= maxRetries-1 {
+				eventChan <- ProviderEvent{Type: EventError, Error: fmt.Errorf("max retries reached: %w", err)}
 				return
 			}
+
+			shouldRetry, sleepDuration := i.retryPolicy.ShouldRetry(attempts, err)
 			if !shouldRetry {
-				eventChan <- ProviderEvent{
-					Type:  EventError,
-					Error: err,
-				}
+				eventChan <- ProviderEvent{Type: EventError, Error: err}
 				return
 			}
 
 			logging.Info("Retrying stream request", "attempt", attempts+1, "sleep", sleepDuration)
 			time.Sleep(sleepDuration)
-		}
 
-		if err != nil {
-			eventChan <- ProviderEvent{
-				Type:  EventError,
-				Error: err,
+			// Resend with the partial assistant content accumulated so far
+			// as an extra message, so a resumed stream continues instead
+			// of making the model repeat itself from scratch.
+			if state.content != "" {
+				resumeMsg := openai.AssistantMessage(state.content)
+				if resumeMsgIdx == -1 {
+					params.Messages = append(params.Messages, resumeMsg)
+					resumeMsgIdx = len(params.Messages) - 1
+				} else {
+					params.Messages[resumeMsgIdx] = resumeMsg
+				}
 			}
-			return
+
+			// A fresh connection restarts its own Delta.ToolCalls[].Index
+			// from 0, so any tool calls accumulated on the abandoned
+			// attempt no longer correspond to the new stream's indices.
+			// Drop them; unlike content, which is kept and resent above,
+			// tool calls have to be resent by the model from scratch on the
+			// resumed connection.
+			state.toolCalls = nil
 		}
+	}()
 
-		defer stream.Close()
+	return eventChan
+}
 
-		eventChan <- ProviderEvent{
-			Type: EventContentStart,
-		}
+// streamState accumulates a single logical stream response across however
+// many physical connections it took (reconnects happen transparently on
+// retryable errors).
+type streamState struct {
+	content      string
+	thinking     string
+	toolCalls    []message.ToolCall
+	usage        TokenUsage
+	finishReason message.FinishReason
+}
 
-		var content string
-		var toolCalls []message.ToolCall
-		var usage TokenUsage
-		var finishReason message.FinishReason
+// recvStream reads chunks off an in-flight stream, emitting provider events
+// for each, until it hits a terminal EOF (nil error) or an error the caller
+// should decide whether to retry.
+func (i *infineonClient) recvStream(stream openai.ChatCompletionStream, eventChan chan<- ProviderEvent, state *streamState) error {
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-		for {
-			chunk, err := stream.Recv()
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			if err != nil {
-				eventChan <- ProviderEvent{
-					Type:  EventError,
-					Error: err,
-				}
-				return
+		// The final chunk of an include_usage stream carries the usage
+		// block and an empty Choices slice.
+		if chunk.Usage.TotalTokens > 0 {
+			state.usage = TokenUsage{
+				InputTokens:     int64(chunk.Usage.PromptTokens),
+				OutputTokens:    int64(chunk.Usage.CompletionTokens),
+				CacheReadTokens: int64(chunk.Usage.PromptTokensDetails.CachedTokens),
 			}
+			eventChan <- ProviderEvent{Type: EventUsageDelta, Usage: &state.usage}
+		}
 
-			if len(chunk.Choices) == 0 {
-				continue
-			}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
 
-			choice := chunk.Choices[0]
+		choice := chunk.Choices[0]
 
-			// Handle content delta
-			if choice.Delta.Content != nil {
-				content += *choice.Delta.Content
-				eventChan <- ProviderEvent{
-					Type:    EventContentDelta,
-					Content: *choice.Delta.Content,
-				}
+		// Handle reasoning/thinking delta, kept separate from the final
+		// answer so the TUI can render it in its own collapsible pane.
+		if i.providerOptions.model.CanReason && choice.Delta.ReasoningContent != "" {
+			state.thinking += choice.Delta.ReasoningContent
+			eventChan <- ProviderEvent{
+				Type:     EventThinkingDelta,
+				Thinking: choice.Delta.ReasoningContent,
 			}
+		}
 
-			// Handle tool calls
-			if len(choice.Delta.ToolCalls) > 0 {
-				for _, toolCallDelta := range choice.Delta.ToolCalls {
-					if toolCallDelta.Index < 0 || toolCallDelta.Index >= len(toolCalls) {
-						// New tool call
-						if toolCallDelta.ID != nil {
-							toolCall := message.ToolCall{
-								ID:   *toolCallDelta.ID,
-								Name: "",
-							}
-							toolCalls = append(toolCalls, toolCall)
-
-							eventChan <- ProviderEvent{
-								Type:     EventToolUseStart,
-								ToolCall: &toolCall,
-							}
-						}
-					}
+		// Handle content delta
+		if choice.Delta.Content != nil {
+			state.content += *choice.Delta.Content
+			eventChan <- ProviderEvent{
+				Type:    EventContentDelta,
+				Content: *choice.Delta.Content,
+			}
+		}
 
-					if toolCallDelta.Index >= 0 && toolCallDelta.Index < len(toolCalls) {
-						// Update existing tool call
-						if toolCallDelta.Function.Name != nil {
-							toolCalls[toolCallDelta.Index].Name = *toolCallDelta.Function.Name
+		// Handle tool calls
+		if len(choice.Delta.ToolCalls) > 0 {
+			for _, toolCallDelta := range choice.Delta.ToolCalls {
+				if toolCallDelta.Index < 0 || toolCallDelta.Index >= len(state.toolCalls) {
+					// New tool call
+					if toolCallDelta.ID != nil {
+						toolCall := message.ToolCall{
+							ID:   *toolCallDelta.ID,
+							Name: "",
 						}
+						state.toolCalls = append(state.toolCalls, toolCall)
 
-						if toolCallDelta.Function.Arguments != nil {
-							toolCalls[toolCallDelta.Index].Input += *toolCallDelta.Function.Arguments
-							eventChan <- ProviderEvent{
-								Type: EventToolUseDelta,
-								ToolCall: &message.ToolCall{
-									ID:    toolCalls[toolCallDelta.Index].ID,
-									Name:  toolCalls[toolCallDelta.Index].Name,
-									Input: *toolCallDelta.Function.Arguments,
-								},
-							}
+						eventChan <- ProviderEvent{
+							Type:     EventToolUseStart,
+							ToolCall: &toolCall,
 						}
 					}
 				}
-			}
 
-			if choice.FinishReason != "" {
-				finishReason = i.finishReason(choice.FinishReason)
-			}
-		}
+				if toolCallDelta.Index >= 0 && toolCallDelta.Index < len(state.toolCalls) {
+					// Update existing tool call
+					if toolCallDelta.Function.Name != nil {
+						state.toolCalls[toolCallDelta.Index].Name = *toolCallDelta.Function.Name
+					}
 
-		// Send tool use stop events for all tool calls
-		for _, toolCall := range toolCalls {
-			eventChan <- ProviderEvent{
-				Type:     EventToolUseStop,
-				ToolCall: &toolCall,
+					if toolCallDelta.Function.Arguments != nil {
+						state.toolCalls[toolCallDelta.Index].Input += *toolCallDelta.Function.Arguments
+						eventChan <- ProviderEvent{
+							Type: EventToolUseDelta,
+							ToolCall: &message.ToolCall{
+								ID:    state.toolCalls[toolCallDelta.Index].ID,
+								Name:  state.toolCalls[toolCallDelta.Index].Name,
+								Input: *toolCallDelta.Function.Arguments,
+							},
+						}
+					}
+				}
 			}
 		}
 
-		eventChan <- ProviderEvent{
-			Type: EventContentStop,
-		}
-
-		eventChan <- ProviderEvent{
-			Type: EventComplete,
-			Response: &ProviderResponse{
-				Content:      content,
-				ToolCalls:    toolCalls,
-				Usage:        usage,
-				FinishReason: finishReason,
-			},
+		if choice.FinishReason != "" {
+			state.finishReason = i.finishReason(choice.FinishReason)
 		}
-	}()
-
-	return eventChan
-}
-
-func (i *infineonClient) shouldRetry(attempts int, err error) (bool, int64, error) {
-	var apiErr *shared.APIError
-	if !errors.As(err, &apiErr) {
-		return false, 0, nil
 	}
+}
 
-	// Rate limit errors
-	if apiErr.StatusCode == 429 {
-		retryAfterHeader := apiErr.Response.Header.Get("Retry-After")
-		if retryAfterHeader != "" {
-			retryAfter, parseErr := time.ParseDuration(retryAfterHeader + "s")
-			if parseErr == nil {
-				return true, retryAfter.Milliseconds(), nil
-			}
+func (i *infineonClient) finishStream(eventChan chan<- ProviderEvent, state *streamState) {
+	for _, toolCall := range state.toolCalls {
+		eventChan <- ProviderEvent{
+			Type:     EventToolUseStop,
+			ToolCall: &toolCall,
 		}
-
-		// Exponential backoff
-		backoff := int64(1000 * (1 << attempts))
-		return true, backoff, nil
 	}
 
-	// Server errors
-	if apiErr.StatusCode >= 500 && apiErr.StatusCode < 600 {
-		if attempts >= maxRetries-1 {
-			return false, 0, fmt.Errorf("max retries reached: %w", err)
-		}
-		backoff := int64(1000 * (1 << attempts))
-		return true, backoff, nil
+	eventChan <- ProviderEvent{Type: EventContentStop}
+
+	eventChan <- ProviderEvent{
+		Type: EventComplete,
+		Response: &ProviderResponse{
+			Content:      state.content,
+			Thinking:     state.thinking,
+			ToolCalls:    state.toolCalls,
+			Usage:        state.usage,
+			FinishReason: state.finishReason,
+		},
 	}
-
-	return false, 0, nil
 }
 
 func (i *infineonClient) toolCalls(completion openai.ChatCompletion) []message.ToolCall {
@@ -445,8 +506,9 @@ func (i *infineonClient) toolCalls(completion openai.ChatCompletion) []message.T
 
 func (i *infineonClient) usage(completion openai.ChatCompletion) TokenUsage {
 	return TokenUsage{
-		InputTokens:  int64(completion.Usage.PromptTokens),
-		OutputTokens: int64(completion.Usage.CompletionTokens),
+		InputTokens:     int64(completion.Usage.PromptTokens),
+		OutputTokens:    int64(completion.Usage.CompletionTokens),
+		CacheReadTokens: int64(completion.Usage.PromptTokensDetails.CachedTokens),
 	}
 }
 
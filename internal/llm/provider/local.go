@@ -0,0 +1,332 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/models"
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/tools"
+	"https://github.com/0xJohnnes/cli4ifx/internal/logging"
+	"https://github.com/0xJohnnes/cli4ifx/internal/message"
+)
+
+// localOptions configures localClient, the provider for any self-hosted
+// OpenAI-compatible endpoint (Ollama, LocalAI, llama.cpp, vLLM, LM Studio).
+type localOptions struct {
+	baseURL      string
+	modelRefresh time.Duration
+	extraHeaders map[string]string
+}
+
+type LocalOption func(*localOptions)
+
+// WithLocalBaseURL points the local provider at a specific OpenAI-compatible
+// endpoint, e.g. "http://localhost:11434/v1" for Ollama.
+func WithLocalBaseURL(baseURL string) LocalOption {
+	return func(options *localOptions) {
+		options.baseURL = baseURL
+	}
+}
+
+// WithLocalModelRefresh re-queries /v1/models on the configured interval and
+// registers any model the server reports in models.SupportedModels, instead
+// of requiring hardcoded entries. Models the server stops reporting are not
+// removed.
+func WithLocalModelRefresh(interval time.Duration) LocalOption {
+	return func(options *localOptions) {
+		options.modelRefresh = interval
+	}
+}
+
+func WithLocalExtraHeaders(headers map[string]string) LocalOption {
+	return func(options *localOptions) {
+		options.extraHeaders = headers
+	}
+}
+
+type localClient struct {
+	providerOptions providerClientOptions
+	options         localOptions
+	client          openai.Client
+	retryPolicy     RetryPolicy
+
+	refreshOnce sync.Once
+	stopRefresh chan struct{}
+}
+
+type LocalClient ProviderClient
+
+func newLocalClient(opts providerClientOptions) LocalClient {
+	localOpts := localOptions{
+		baseURL: "http://localhost:11434/v1", // Ollama's default OpenAI-compatible endpoint
+	}
+	for _, o := range opts.localOptions {
+		o(&localOpts)
+	}
+
+	openaiClientOptions := []option.RequestOption{
+		option.WithBaseURL(localOpts.baseURL),
+	}
+	// Local servers generally don't require an API key, but pass one
+	// through if the caller configured it (some deployments sit behind
+	// an auth proxy).
+	if opts.apiKey != "" {
+		openaiClientOptions = append(openaiClientOptions, option.WithAPIKey(opts.apiKey))
+	}
+	for key, value := range localOpts.extraHeaders {
+		openaiClientOptions = append(openaiClientOptions, option.WithHeader(key, value))
+	}
+
+	retryPolicy := opts.retryPolicy
+	if retryPolicy == nil {
+		retryPolicy = &ExponentialBackoff{Base: time.Second, Max: 60 * time.Second, Jitter: true}
+	}
+
+	client := &localClient{
+		providerOptions: opts,
+		options:         localOpts,
+		client:          openai.NewClient(openaiClientOptions...),
+		retryPolicy:     retryPolicy,
+		stopRefresh:     make(chan struct{}),
+	}
+
+	discoverCtx, cancel := context.WithTimeout(context.Background(), modelDiscoveryTimeout)
+	client.discoverModels(discoverCtx)
+	cancel()
+
+	if localOpts.modelRefresh > 0 {
+		go client.refreshModelsLoop()
+	}
+
+	return client
+}
+
+// modelDiscoveryTimeout bounds the synchronous /v1/models probe newLocalClient
+// does at construction time, so pointing ProviderLocal at an unreachable or
+// slow endpoint fails fast (discovery just logs a warning and no models are
+// registered) instead of hanging the caller indefinitely.
+const modelDiscoveryTimeout = 5 * time.Second
+
+// discoverModels queries /v1/models and merges whatever the server reports
+// into models.SupportedModels, so users don't need hardcoded model IDs to
+// talk to an arbitrary OpenAI-compatible backend.
+func (l *localClient) discoverModels(ctx context.Context) {
+	page, err := l.client.Models.List(ctx)
+	if err != nil {
+		logging.Warn("Failed to discover local models", "error", err)
+		return
+	}
+
+	for _, m := range page.Data {
+		id := models.ModelID("local-" + m.ID)
+		models.RegisterModel(models.Model{
+			ID:               id,
+			Name:             m.ID,
+			Provider:         models.ProviderLocal,
+			APIModel:         m.ID,
+			ContextWindow:    0, // unknown until the server reports it
+			DefaultMaxTokens: 4096,
+		})
+	}
+}
+
+func (l *localClient) refreshModelsLoop() {
+	ticker := time.NewTicker(l.options.modelRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.discoverModels(context.Background())
+		case <-l.stopRefresh:
+			return
+		}
+	}
+}
+
+// Close stops the background model-refresh loop started when the client
+// was built with WithLocalModelRefresh. It's safe to call more than once
+// and safe to call even if no refresh loop was started. Provider.Close
+// reaches this through baseProvider's optional-closer check.
+func (l *localClient) Close() {
+	l.refreshOnce.Do(func() {
+		close(l.stopRefresh)
+	})
+}
+
+func (l *localClient) convertMessages(messages []message.Message) []openai.ChatCompletionMessageParamUnion {
+	// Message conversion is identical to the Infineon client: both are
+	// talking to an OpenAI-compatible chat completions endpoint.
+	return (&infineonClient{providerOptions: l.providerOptions}).convertMessages(messages)
+}
+
+func (l *localClient) convertTools(tools []tools.BaseTool) []openai.ChatCompletionToolParam {
+	return (&infineonClient{providerOptions: l.providerOptions}).convertTools(tools)
+}
+
+func (l *localClient) finishReason(reason string) message.FinishReason {
+	return (&infineonClient{}).finishReason(reason)
+}
+
+func (l *localClient) preparedParams(messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolParam) (openai.ChatCompletionNewParams, error) {
+	params := openai.ChatCompletionNewParams{
+		Messages: messages,
+		Model:    l.providerOptions.model.APIModel,
+	}
+	if len(tools) > 0 {
+		params.Tools = tools
+	}
+	if l.providerOptions.maxTokens > 0 {
+		params.MaxTokens = openai.Int(int(l.providerOptions.maxTokens))
+	}
+
+	if err := applyResponseFormat(&params, l.providerOptions.responseFormat); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+func (l *localClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
+	openaiMessages := l.convertMessages(messages)
+	openaiTools := l.convertTools(tools)
+	params, err := l.preparedParams(openaiMessages, openaiTools)
+	if err != nil {
+		return nil, err
+	}
+
+	var completion openai.ChatCompletion
+	for attempts := 0; attempts < maxRetries; attempts++ {
+		if paceErr := pace(ctx, l.retryPolicy); paceErr != nil {
+			return nil, paceErr
+		}
+
+		var httpResp *http.Response
+		completion, err = l.client.Chat.Completions.New(ctx, params, option.WithResponseInto(&httpResp))
+		recordHeaders(l.retryPolicy, responseHeader(httpResp, err))
+		if err == nil {
+			break
+		}
+
+		if attempts >= maxRetries-1 {
+			return nil, fmt.Errorf("max retries reached: %w", err)
+		}
+
+		shouldRetry, sleepDuration := l.retryPolicy.ShouldRetry(attempts, err)
+		if !shouldRetry {
+			return nil, err
+		}
+
+		logging.Info("Retrying local request", "attempt", attempts+1, "sleep", sleepDuration)
+		time.Sleep(sleepDuration)
+	}
+
+	if len(completion.Choices) == 0 {
+		return nil, errors.New("no choices returned")
+	}
+
+	choice := completion.Choices[0]
+	content := choice.Message.Content
+
+	usage := TokenUsage{
+		InputTokens:  int64(completion.Usage.PromptTokens),
+		OutputTokens: int64(completion.Usage.CompletionTokens),
+	}
+	// Many self-hosted servers don't populate usage at all; fall back to
+	// a rough tokenizer estimate so callers still get non-zero numbers.
+	if usage.InputTokens == 0 && usage.OutputTokens == 0 {
+		usage = l.estimateUsage(messages, content)
+	}
+
+	return &ProviderResponse{
+		Content:      content,
+		Usage:        usage,
+		FinishReason: l.finishReason(string(choice.FinishReason)),
+	}, nil
+}
+
+func (l *localClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	openaiMessages := l.convertMessages(messages)
+	openaiTools := l.convertTools(tools)
+	params, err := l.preparedParams(openaiMessages, openaiTools)
+	if err != nil {
+		return errorEventChan(err)
+	}
+	params.Stream = openai.Bool(true)
+
+	eventChan := make(chan ProviderEvent)
+
+	go func() {
+		defer close(eventChan)
+
+		if paceErr := pace(ctx, l.retryPolicy); paceErr != nil {
+			eventChan <- ProviderEvent{Type: EventError, Error: paceErr}
+			return
+		}
+
+		var httpResp *http.Response
+		stream := l.client.Chat.Completions.NewStreaming(ctx, params, option.WithResponseInto(&httpResp))
+		defer stream.Close()
+
+		eventChan <- ProviderEvent{Type: EventContentStart}
+
+		var content string
+		var finishReason message.FinishReason
+		var usage TokenUsage
+
+		for stream.Next() {
+			chunk := stream.Current()
+			if len(chunk.Choices) == 0 {
+				continue
+			}
+			choice := chunk.Choices[0]
+			if choice.Delta.Content != "" {
+				content += choice.Delta.Content
+				eventChan <- ProviderEvent{Type: EventContentDelta, Content: choice.Delta.Content}
+			}
+			if choice.FinishReason != "" {
+				finishReason = l.finishReason(string(choice.FinishReason))
+			}
+		}
+
+		err := stream.Err()
+		recordHeaders(l.retryPolicy, responseHeader(httpResp, err))
+		if err != nil && !errors.Is(err, io.EOF) {
+			eventChan <- ProviderEvent{Type: EventError, Error: err}
+			return
+		}
+
+		usage = l.estimateUsage(messages, content)
+
+		eventChan <- ProviderEvent{Type: EventContentStop}
+		eventChan <- ProviderEvent{
+			Type: EventComplete,
+			Response: &ProviderResponse{
+				Content:      content,
+				Usage:        usage,
+				FinishReason: finishReason,
+			},
+		}
+	}()
+
+	return eventChan
+}
+
+// estimateUsage approximates token counts for servers that don't report
+// usage, using the common ~4-characters-per-token heuristic.
+func (l *localClient) estimateUsage(messages []message.Message, content string) TokenUsage {
+	var inputChars int
+	for _, msg := range messages {
+		inputChars += len(msg.Content().String())
+	}
+	return TokenUsage{
+		InputTokens:  int64(inputChars)/4 + 1,
+		OutputTokens: int64(len(content))/4 + 1,
+	}
+}
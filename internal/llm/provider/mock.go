@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/tools"
+	"https://github.com/0xJohnnes/cli4ifx/internal/message"
+)
+
+// MockResponse is a single scripted step. Each call to send/stream against a
+// mockClient consumes the next MockResponse in the script, in order.
+type MockResponse struct {
+	Response *ProviderResponse
+	// Events, if set, is replayed verbatim by stream() instead of being
+	// synthesized from Response. Use this to script tool-call
+	// start/delta/stop sequences or thinking deltas.
+	Events  []ProviderEvent
+	Err     error
+	Latency time.Duration
+	// OnCall, if set, is invoked with the messages and tools this step was
+	// called with, before the step's response/error/events are returned.
+	// It's meant for tests that need to assert on the conversation history
+	// a caller builds across multiple rounds (e.g. the agent package's tool
+	// loop), without mockClient having to expose its internals.
+	OnCall func(messages []message.Message, tools []tools.BaseTool)
+}
+
+type mockOptions struct {
+	script []MockResponse
+}
+
+type MockOption func(*mockOptions)
+
+// WithMockScript sets the ordered list of responses the mock client returns,
+// one per call to SendMessages/StreamResponse. Calls beyond the end of the
+// script return an error.
+func WithMockScript(script ...MockResponse) MockOption {
+	return func(options *mockOptions) {
+		options.script = script
+	}
+}
+
+// WithMockOptions is the mock-provider analogue of WithInfineonOptions.
+func WithMockOptions(mockOptions ...MockOption) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.mockOptions = mockOptions
+	}
+}
+
+type mockClient struct {
+	providerOptions providerClientOptions
+	options         mockOptions
+
+	mu    sync.Mutex
+	calls int
+}
+
+type MockClient ProviderClient
+
+func newMockClient(opts providerClientOptions) MockClient {
+	mockOpts := mockOptions{}
+	for _, o := range opts.mockOptions {
+		o(&mockOpts)
+	}
+
+	return &mockClient{
+		providerOptions: opts,
+		options:         mockOpts,
+	}
+}
+
+// nextStep returns the next scripted response and advances the call counter.
+func (m *mockClient) nextStep() (MockResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.calls >= len(m.options.script) {
+		return MockResponse{}, errors.New("mock: script exhausted, no response scripted for this call")
+	}
+
+	step := m.options.script[m.calls]
+	m.calls++
+	return step, nil
+}
+
+func (m *mockClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
+	step, err := m.nextStep()
+	if err != nil {
+		return nil, err
+	}
+	if step.OnCall != nil {
+		step.OnCall(messages, tools)
+	}
+
+	if step.Latency > 0 {
+		select {
+		case <-time.After(step.Latency):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if step.Err != nil {
+		return nil, step.Err
+	}
+
+	if step.Response == nil {
+		return nil, errors.New("mock: scripted step has no response")
+	}
+
+	return step.Response, nil
+}
+
+func (m *mockClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	eventChan := make(chan ProviderEvent)
+
+	go func() {
+		defer close(eventChan)
+
+		step, err := m.nextStep()
+		if err != nil {
+			eventChan <- ProviderEvent{Type: EventError, Error: err}
+			return
+		}
+		if step.OnCall != nil {
+			step.OnCall(messages, tools)
+		}
+
+		if step.Latency > 0 {
+			select {
+			case <-time.After(step.Latency):
+			case <-ctx.Done():
+				eventChan <- ProviderEvent{Type: EventError, Error: ctx.Err()}
+				return
+			}
+		}
+
+		if step.Err != nil {
+			eventChan <- ProviderEvent{Type: EventError, Error: step.Err}
+			return
+		}
+
+		if len(step.Events) > 0 {
+			for _, event := range step.Events {
+				select {
+				case eventChan <- event:
+				case <-ctx.Done():
+					eventChan <- ProviderEvent{Type: EventError, Error: ctx.Err()}
+					return
+				}
+			}
+			return
+		}
+
+		if step.Response == nil {
+			eventChan <- ProviderEvent{Type: EventError, Error: errors.New("mock: scripted step has no response or events")}
+			return
+		}
+
+		eventChan <- ProviderEvent{Type: EventContentStart}
+		if step.Response.Content != "" {
+			eventChan <- ProviderEvent{Type: EventContentDelta, Content: step.Response.Content}
+		}
+		for _, call := range step.Response.ToolCalls {
+			call := call
+			eventChan <- ProviderEvent{Type: EventToolUseStart, ToolCall: &call}
+			eventChan <- ProviderEvent{Type: EventToolUseStop, ToolCall: &call}
+		}
+		eventChan <- ProviderEvent{Type: EventContentStop}
+		eventChan <- ProviderEvent{Type: EventComplete, Response: step.Response}
+	}()
+
+	return eventChan
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"https://github.com/0xJohnnes/cli4ifx/internal/llm/models"
+)
+
+func newTestMockProvider(t *testing.T, script ...MockResponse) Provider {
+	t.Helper()
+	p, err := NewProvider(models.ProviderMock,
+		WithModel(models.Model{CostPer1MIn: 1, CostPer1MOut: 2}),
+		WithMockOptions(WithMockScript(script...)),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	return p
+}
+
+func TestMockClient_SendMessages(t *testing.T) {
+	p := newTestMockProvider(t, MockResponse{
+		Response: &ProviderResponse{Content: "hello", Usage: TokenUsage{InputTokens: 10, OutputTokens: 5}},
+	})
+
+	resp, err := p.SendMessages(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("SendMessages: %v", err)
+	}
+	if resp.Content != "hello" {
+		t.Errorf("Content = %q, want %q", resp.Content, "hello")
+	}
+
+	stats := p.Stats()
+	if stats.RequestCount != 1 {
+		t.Errorf("RequestCount = %d, want 1", stats.RequestCount)
+	}
+	if stats.TotalUsage.InputTokens != 10 || stats.TotalUsage.OutputTokens != 5 {
+		t.Errorf("TotalUsage = %+v, want input=10 output=5", stats.TotalUsage)
+	}
+	if stats.TotalCost != CostOf(p.Model(), TokenUsage{InputTokens: 10, OutputTokens: 5}) {
+		t.Errorf("TotalCost = %v, want the cost of the scripted usage", stats.TotalCost)
+	}
+}
+
+func TestMockClient_SendMessages_ScriptedError(t *testing.T) {
+	wantErr := errors.New("scripted failure")
+	p := newTestMockProvider(t, MockResponse{Err: wantErr})
+
+	_, err := p.SendMessages(context.Background(), nil, nil)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("err = %v, want %v", err, wantErr)
+	}
+	// A failed call never records usage.
+	if stats := p.Stats(); stats.RequestCount != 0 {
+		t.Errorf("RequestCount = %d, want 0 after a failed call", stats.RequestCount)
+	}
+}
+
+func TestMockClient_SendMessages_ScriptExhausted(t *testing.T) {
+	p := newTestMockProvider(t, MockResponse{Response: &ProviderResponse{Content: "only one"}})
+
+	if _, err := p.SendMessages(context.Background(), nil, nil); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if _, err := p.SendMessages(context.Background(), nil, nil); err == nil {
+		t.Fatal("expected the second call to fail once the script is exhausted")
+	}
+}
+
+func TestMockClient_StreamResponse_SynthesizesEventsFromResponse(t *testing.T) {
+	p := newTestMockProvider(t, MockResponse{
+		Response: &ProviderResponse{Content: "partial reply", Usage: TokenUsage{InputTokens: 1, OutputTokens: 1}},
+	})
+
+	var types []EventType
+	for event := range p.StreamResponse(context.Background(), nil, nil) {
+		types = append(types, event.Type)
+	}
+
+	want := []EventType{EventContentStart, EventContentDelta, EventContentStop, EventComplete}
+	if len(types) != len(want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+	for i, w := range want {
+		if types[i] != w {
+			t.Errorf("event %d = %s, want %s", i, types[i], w)
+		}
+	}
+}
+
+func TestMockClient_StreamResponse_ReplaysScriptedEvents(t *testing.T) {
+	// Scripting raw Events lets a test exercise reasoning-content delivery
+	// (thinking deltas) without needing a live reasoning-capable model.
+	p := newTestMockProvider(t, MockResponse{
+		Events: []ProviderEvent{
+			{Type: EventContentStart},
+			{Type: EventThinkingDelta, Thinking: "considering the question..."},
+			{Type: EventContentDelta, Content: "42"},
+			{Type: EventContentStop},
+			{Type: EventComplete, Response: &ProviderResponse{Content: "42", Thinking: "considering the question..."}},
+		},
+	})
+
+	var sawThinking string
+	var types []EventType
+	for event := range p.StreamResponse(context.Background(), nil, nil) {
+		types = append(types, event.Type)
+		if event.Type == EventThinkingDelta {
+			sawThinking = event.Thinking
+		}
+	}
+
+	if sawThinking != "considering the question..." {
+		t.Errorf("thinking delta = %q, want the scripted reasoning text", sawThinking)
+	}
+	if types[len(types)-1] != EventComplete {
+		t.Errorf("last event = %s, want %s", types[len(types)-1], EventComplete)
+	}
+}
+
+func TestMockClient_Latency_RespectsContextCancellation(t *testing.T) {
+	p := newTestMockProvider(t, MockResponse{
+		Response: &ProviderResponse{Content: "too slow"},
+		Latency:  time.Hour,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := p.SendMessages(ctx, nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestMockProvider_Close_IsANoOp(t *testing.T) {
+	p := newTestMockProvider(t, MockResponse{Response: &ProviderResponse{}})
+	// mockClient doesn't hold background resources; Close must still be
+	// safe to call, same as for every other provider.
+	p.Close()
+}
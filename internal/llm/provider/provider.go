@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 
 	"https://github.com/0xJohnnes/cli4ifx/internal/llm/models"
 	"https://github.com/0xJohnnes/cli4ifx/internal/llm/tools"
@@ -25,6 +26,17 @@ const (
 	EventComplete      EventType = "complete"
 	EventError         EventType = "error"
 	EventWarning       EventType = "warning"
+
+	// EventToolResult and EventIterationStart are emitted by the agent
+	// loop (internal/llm/agent) as it drives tool execution across
+	// multiple provider calls; the provider package itself never emits
+	// them.
+	EventToolResult     EventType = "tool_result"
+	EventIterationStart EventType = "iteration_start"
+
+	// EventUsageDelta reports incremental token-usage updates as they
+	// arrive mid-stream, ahead of the final totals on EventComplete.
+	EventUsageDelta EventType = "usage_delta"
 )
 
 type TokenUsage struct {
@@ -36,11 +48,22 @@ type TokenUsage struct {
 
 type ProviderResponse struct {
 	Content      string
+	Thinking     string
 	ToolCalls    []message.ToolCall
 	Usage        TokenUsage
 	FinishReason message.FinishReason
 }
 
+// ReasoningEffort controls how much a reasoning-capable model (see
+// models.Model.CanReason) "thinks" before answering.
+type ReasoningEffort string
+
+const (
+	ReasoningEffortLow    ReasoningEffort = "low"
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	ReasoningEffortHigh   ReasoningEffort = "high"
+)
+
 type ProviderEvent struct {
 	Type EventType
 
@@ -49,6 +72,14 @@ type ProviderEvent struct {
 	Response *ProviderResponse
 	ToolCall *message.ToolCall
 	Error    error
+
+	// ToolResult and Iteration are only populated by the agent loop's
+	// EventToolResult / EventIterationStart events.
+	ToolResult *message.ToolResult
+	Iteration  int
+
+	// Usage is only populated on EventUsageDelta.
+	Usage *TokenUsage
 }
 type Provider interface {
 	SendMessages(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error)
@@ -56,6 +87,15 @@ type Provider interface {
 	StreamResponse(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent
 
 	Model() models.Model
+
+	// Stats returns the cumulative token usage and cost this provider has
+	// accumulated across every SendMessages/StreamResponse call so far.
+	Stats() ProviderStats
+
+	// Close releases any background resources a client holds (e.g. the
+	// local provider's model-refresh goroutine). It's a no-op for clients
+	// that don't need it.
+	Close()
 }
 
 type providerClientOptions struct {
@@ -66,6 +106,11 @@ type providerClientOptions struct {
 
 	openaiOptions   []OpenAIOption
 	infineonOptions []InfineonOption
+	localOptions    []LocalOption
+	mockOptions     []MockOption
+	responseFormat  *responseFormat
+	retryPolicy     RetryPolicy
+	reasoningEffort ReasoningEffort
 }
 
 type ProviderClientOption func(*providerClientOptions)
@@ -78,6 +123,9 @@ type ProviderClient interface {
 type baseProvider[C ProviderClient] struct {
 	options providerClientOptions
 	client  C
+
+	statsMu sync.Mutex
+	stats   ProviderStats
 }
 
 func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption) (Provider, error) {
@@ -96,13 +144,31 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 			options: clientOptions,
 			client:  newInfineonClient(clientOptions),
 		}, nil
+	case models.ProviderLocal:
+		return &baseProvider[LocalClient]{
+			options: clientOptions,
+			client:  newLocalClient(clientOptions),
+		}, nil
 	case models.ProviderMock:
-		// TODO: implement mock client for test
-		panic("not implemented")
+		return &baseProvider[MockClient]{
+			options: clientOptions,
+			client:  newMockClient(clientOptions),
+		}, nil
 	}
 	return nil, fmt.Errorf("provider not supported: %s", providerName)
 }
 
+// errorEventChan returns a channel that immediately delivers a single
+// EventError for err and closes, for clients that discover a fatal error
+// (e.g. a misconfigured response format) before they have anything worth
+// spawning a goroutine for.
+func errorEventChan(err error) <-chan ProviderEvent {
+	eventChan := make(chan ProviderEvent, 1)
+	eventChan <- ProviderEvent{Type: EventError, Error: err}
+	close(eventChan)
+	return eventChan
+}
+
 func (p *baseProvider[C]) cleanMessages(messages []message.Message) (cleaned []message.Message) {
 	for _, msg := range messages {
 		// The message has no content
@@ -116,7 +182,11 @@ func (p *baseProvider[C]) cleanMessages(messages []message.Message) (cleaned []m
 
 func (p *baseProvider[C]) SendMessages(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
 	messages = p.cleanMessages(messages)
-	return p.client.send(ctx, messages, tools)
+	resp, err := p.client.send(ctx, messages, tools)
+	if err == nil {
+		p.recordUsage(resp.Usage)
+	}
+	return resp, err
 }
 
 func (p *baseProvider[C]) Model() models.Model {
@@ -125,7 +195,51 @@ func (p *baseProvider[C]) Model() models.Model {
 
 func (p *baseProvider[C]) StreamResponse(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
 	messages = p.cleanMessages(messages)
-	return p.client.stream(ctx, messages, tools)
+	inner := p.client.stream(ctx, messages, tools)
+
+	out := make(chan ProviderEvent)
+	go func() {
+		defer close(out)
+		for event := range inner {
+			if event.Type == EventComplete && event.Response != nil {
+				p.recordUsage(event.Response.Usage)
+			}
+			out <- event
+		}
+	}()
+	return out
+}
+
+// recordUsage accumulates token usage and its monetary cost into this
+// provider's running stats.
+func (p *baseProvider[C]) recordUsage(usage TokenUsage) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	p.stats.RequestCount++
+	p.stats.TotalUsage.InputTokens += usage.InputTokens
+	p.stats.TotalUsage.OutputTokens += usage.OutputTokens
+	p.stats.TotalUsage.CacheCreationTokens += usage.CacheCreationTokens
+	p.stats.TotalUsage.CacheReadTokens += usage.CacheReadTokens
+	p.stats.TotalCost += CostOf(p.options.model, usage)
+}
+
+func (p *baseProvider[C]) Stats() ProviderStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	return p.stats
+}
+
+// closer is implemented by clients that hold background resources needing
+// an explicit shutdown (e.g. localClient's model-refresh goroutine).
+type closer interface {
+	Close()
+}
+
+func (p *baseProvider[C]) Close() {
+	if c, ok := any(p.client).(closer); ok {
+		c.Close()
+	}
 }
 
 func WithAPIKey(apiKey string) ProviderClientOption {
@@ -163,3 +277,26 @@ func WithInfineonOptions(infineonOptions ...InfineonOption) ProviderClientOption
 		options.infineonOptions = infineonOptions
 	}
 }
+
+func WithLocalOptions(localOptions ...LocalOption) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.localOptions = localOptions
+	}
+}
+
+// WithRetryPolicy overrides the default ExponentialBackoff retry policy
+// used by the Infineon and local clients.
+func WithRetryPolicy(policy RetryPolicy) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.retryPolicy = policy
+	}
+}
+
+// WithReasoningEffort sets how hard a reasoning-capable model should think
+// before answering. It's a no-op for models where models.Model.CanReason is
+// false.
+func WithReasoningEffort(effort ReasoningEffort) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.reasoningEffort = effort
+	}
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
+)
+
+// ResponseFormatMode selects how a provider should constrain its output.
+type ResponseFormatMode string
+
+const (
+	// FormatJSON asks the model for any syntactically valid JSON object.
+	FormatJSON ResponseFormatMode = "json_object"
+	// FormatJSONSchema asks the model for JSON matching a specific schema.
+	FormatJSONSchema ResponseFormatMode = "json_schema"
+	// FormatGrammar passes a GBNF grammar through to backends that
+	// support constrained decoding directly (llama.cpp and compatible
+	// local servers). OpenAI itself has no equivalent.
+	FormatGrammar ResponseFormatMode = "grammar"
+)
+
+// responseFormat is the resolved configuration set by WithResponseFormat.
+type responseFormat struct {
+	name   string
+	schema any
+	mode   ResponseFormatMode
+}
+
+// WithResponseFormat constrains a provider's output to JSON, a JSON schema,
+// or (on backends that support it) a GBNF grammar. schema is either the
+// JSON schema document itself (for FormatJSONSchema) or a grammar string
+// (for FormatGrammar); it is ignored for FormatJSON. name identifies the
+// schema and is required for FormatJSONSchema (OpenAI's json_schema
+// response format rejects an empty one with a 400); it's ignored for
+// FormatJSON and FormatGrammar. The error only ever surfaces once a send/
+// stream call actually applies a FormatJSONSchema format with an empty
+// name.
+func WithResponseFormat(name string, schema any, mode ResponseFormatMode) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.responseFormat = &responseFormat{name: name, schema: schema, mode: mode}
+	}
+}
+
+// applyResponseFormat translates a responseFormat into the matching
+// ChatCompletionNewParams field. FormatGrammar has no OpenAI equivalent, so
+// it's passed through as an extra body field for local backends (e.g.
+// llama.cpp's GBNF support) that know how to interpret it. It returns an
+// error if rf requests FormatJSONSchema without a name.
+func applyResponseFormat(params *openai.ChatCompletionNewParams, rf *responseFormat) error {
+	if rf == nil {
+		return nil
+	}
+
+	switch rf.mode {
+	case FormatJSON:
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONObject: &shared.ResponseFormatJSONObjectParam{},
+		}
+	case FormatJSONSchema:
+		if rf.name == "" {
+			return fmt.Errorf("provider: WithResponseFormat requires a schema name for FormatJSONSchema")
+		}
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   rf.name,
+					Schema: rf.schema,
+				},
+			},
+		}
+	case FormatGrammar:
+		params.SetExtraFields(map[string]any{"grammar": rf.schema})
+	}
+	return nil
+}
+
+// DecodeInto unmarshals a ProviderResponse's content into v. It's meant to
+// be paired with WithResponseFormat so callers get a typed value instead of
+// parsing free text themselves.
+func DecodeInto(resp *ProviderResponse, v any) error {
+	if resp == nil {
+		return fmt.Errorf("provider: cannot decode a nil response")
+	}
+	if err := json.Unmarshal([]byte(resp.Content), v); err != nil {
+		return fmt.Errorf("provider: decode response content: %w", err)
+	}
+	return nil
+}
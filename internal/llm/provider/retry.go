@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/shared"
+)
+
+// RetryPolicy decides whether a failed request should be retried and how
+// long to wait before trying again. attempt is the zero-based index of the
+// attempt that just failed (0 after the first failure).
+type RetryPolicy interface {
+	ShouldRetry(attempt int, err error) (bool, time.Duration)
+}
+
+// Pacer is an optional capability a RetryPolicy can implement to delay a
+// request before it's even sent, e.g. to stay under a rate limit the server
+// already told us about on a previous call. Checked before every attempt,
+// including the first.
+type Pacer interface {
+	Pace() time.Duration
+}
+
+// HeaderRecorder is an optional capability a RetryPolicy can implement to
+// observe the response headers of every call, success or failure, so it can
+// track live rate-limit state instead of only reacting to a 429.
+type HeaderRecorder interface {
+	RecordHeaders(header http.Header)
+}
+
+// pace blocks until a policy's Pacer (if any) says it's fine to proceed, or
+// the context is cancelled first.
+func pace(ctx context.Context, policy RetryPolicy) error {
+	pacer, ok := policy.(Pacer)
+	if !ok {
+		return nil
+	}
+	d := pacer.Pace()
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// recordHeaders forwards header to policy's HeaderRecorder (if any). header
+// may be nil, e.g. when a request failed before a response was received.
+func recordHeaders(policy RetryPolicy, header http.Header) {
+	if header == nil {
+		return
+	}
+	if recorder, ok := policy.(HeaderRecorder); ok {
+		recorder.RecordHeaders(header)
+	}
+}
+
+// responseHeader extracts response headers from either a successful raw
+// HTTP response or, failing that, an API error's attached response.
+func responseHeader(resp *http.Response, err error) http.Header {
+	if resp != nil {
+		return resp.Header
+	}
+	var apiErr *shared.APIError
+	if errors.As(err, &apiErr) && apiErr.Response != nil {
+		return apiErr.Response.Header
+	}
+	return nil
+}
+
+// classify separates the errors every RetryPolicy needs to treat
+// differently: API errors carry a status code and headers, context
+// cancellation is never retryable (the caller gave up), a context deadline
+// or a transient network error usually is.
+func classify(err error) (apiErr *shared.APIError, retryableTransient bool) {
+	if errors.As(err, &apiErr) {
+		return apiErr, false
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return nil, false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, true
+	}
+
+	var netErr *net.OpError
+	if errors.As(err, &netErr) {
+		return nil, true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// ExponentialBackoff is the default RetryPolicy. It retries 429s and 5xxs
+// (honoring a server-provided Retry-After header when present) plus
+// transient network errors. With Jitter set it uses decorrelated jitter as
+// recommended for high-concurrency clients: sleep = min(Max, random(Base,
+// prev*3)); without it the wait starts at Base and doubles each time, capped
+// at Max.
+type ExponentialBackoff struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter bool
+
+	mu   sync.Mutex
+	prev time.Duration
+}
+
+func (b *ExponentialBackoff) ShouldRetry(attempt int, err error) (bool, time.Duration) {
+	apiErr, transient := classify(err)
+
+	switch {
+	case apiErr != nil:
+		if apiErr.StatusCode == http.StatusTooManyRequests {
+			if d, ok := retryAfter(apiErr.Response); ok {
+				return true, d
+			}
+			return true, b.next()
+		}
+		if apiErr.StatusCode >= 500 && apiErr.StatusCode < 600 {
+			return true, b.next()
+		}
+		return false, 0
+	case transient:
+		return true, b.next()
+	default:
+		return false, 0
+	}
+}
+
+func (b *ExponentialBackoff) next() time.Duration {
+	base := b.Base
+	if base <= 0 {
+		base = time.Second
+	}
+	max := b.Max
+	if max <= 0 {
+		max = 60 * time.Second
+	}
+
+	if !b.Jitter {
+		b.mu.Lock()
+		if b.prev == 0 {
+			b.prev = base
+		} else {
+			b.prev *= 2
+		}
+		if b.prev > max {
+			b.prev = max
+		}
+		d := b.prev
+		b.mu.Unlock()
+		return d
+	}
+
+	b.mu.Lock()
+	prev := b.prev
+	if prev == 0 {
+		prev = base
+	}
+	upper := prev * 3
+	if upper < base {
+		upper = base
+	}
+	d := base + time.Duration(rand.Int63n(int64(upper-base+1)))
+	if d > max {
+		d = max
+	}
+	b.prev = d
+	b.mu.Unlock()
+	return d
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(header + "s")
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// TokenBucketPolicy paces requests ahead of time using the rate-limit
+// headers a server reports on each response (x-ratelimit-remaining-requests,
+// x-ratelimit-reset-requests), instead of waiting to be told "no" with a
+// 429. RecordHeaders should be called with every response's headers; Pace
+// uses whatever was last recorded to decide how long the caller should wait
+// before its next attempt. ShouldRetry itself ignores that state and
+// delegates to the fallback policy.
+type TokenBucketPolicy struct {
+	fallback RetryPolicy
+
+	mu        sync.Mutex
+	remaining int
+	resetAt   time.Time
+}
+
+func NewTokenBucketPolicy(fallback RetryPolicy) *TokenBucketPolicy {
+	return &TokenBucketPolicy{fallback: fallback}
+}
+
+// RecordHeaders updates the bucket's view of the server's rate limit from
+// the headers of a completed request.
+func (t *TokenBucketPolicy) RecordHeaders(header http.Header) {
+	remaining := header.Get("x-ratelimit-remaining-requests")
+	reset := header.Get("x-ratelimit-reset-requests")
+	if remaining == "" || reset == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if n, err := strconv.Atoi(remaining); err == nil {
+		t.remaining = n
+	}
+	if d, err := time.ParseDuration(reset); err == nil {
+		t.resetAt = time.Now().Add(d)
+	}
+}
+
+// Pace reports how long the caller should wait before its next attempt,
+// based on the rate-limit state from the last call to RecordHeaders. It
+// implements the Pacer capability, so this is consulted before a request is
+// even sent, not just after a 429.
+func (t *TokenBucketPolicy) Pace() time.Duration {
+	t.mu.Lock()
+	remaining, resetAt := t.remaining, t.resetAt
+	t.mu.Unlock()
+
+	if remaining <= 0 && time.Now().Before(resetAt) {
+		return time.Until(resetAt)
+	}
+	return 0
+}
+
+func (t *TokenBucketPolicy) ShouldRetry(attempt int, err error) (bool, time.Duration) {
+	if t.fallback != nil {
+		return t.fallback.ShouldRetry(attempt, err)
+	}
+	return false, 0
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/shared"
+)
+
+func rateLimitErr(header http.Header) error {
+	return &shared.APIError{
+		StatusCode: http.StatusTooManyRequests,
+		Response:   &http.Response{Header: header},
+	}
+}
+
+func serverErr(status int) error {
+	return &shared.APIError{StatusCode: status}
+}
+
+func TestExponentialBackoff_ShouldRetry(t *testing.T) {
+	t.Run("honors Retry-After on a 429", func(t *testing.T) {
+		b := &ExponentialBackoff{Base: time.Second, Max: time.Minute}
+		header := http.Header{}
+		header.Set("Retry-After", "3")
+
+		retry, wait := b.ShouldRetry(0, rateLimitErr(header))
+		if !retry {
+			t.Fatal("expected a 429 to be retryable")
+		}
+		if wait != 3*time.Second {
+			t.Errorf("wait = %v, want 3s from Retry-After", wait)
+		}
+	})
+
+	t.Run("falls back to backoff when no Retry-After is present", func(t *testing.T) {
+		b := &ExponentialBackoff{Base: time.Second, Max: time.Minute}
+		retry, wait := b.ShouldRetry(0, rateLimitErr(nil))
+		if !retry || wait <= 0 {
+			t.Fatalf("retry=%v wait=%v, want a positive backoff", retry, wait)
+		}
+	})
+
+	t.Run("retries 5xx", func(t *testing.T) {
+		b := &ExponentialBackoff{Base: time.Second, Max: time.Minute}
+		retry, _ := b.ShouldRetry(0, serverErr(http.StatusServiceUnavailable))
+		if !retry {
+			t.Error("expected a 503 to be retryable")
+		}
+	})
+
+	t.Run("does not retry other 4xx", func(t *testing.T) {
+		b := &ExponentialBackoff{Base: time.Second, Max: time.Minute}
+		retry, wait := b.ShouldRetry(0, serverErr(http.StatusBadRequest))
+		if retry || wait != 0 {
+			t.Errorf("retry=%v wait=%v, want a 400 to be non-retryable", retry, wait)
+		}
+	})
+
+	t.Run("does not retry a cancelled context", func(t *testing.T) {
+		b := &ExponentialBackoff{Base: time.Second, Max: time.Minute}
+		retry, _ := b.ShouldRetry(0, context.Canceled)
+		if retry {
+			t.Error("a cancelled context should not be retried")
+		}
+	})
+
+	t.Run("retries a deadline exceeded as transient", func(t *testing.T) {
+		b := &ExponentialBackoff{Base: time.Second, Max: time.Minute}
+		retry, wait := b.ShouldRetry(0, context.DeadlineExceeded)
+		if !retry || wait <= 0 {
+			t.Fatalf("retry=%v wait=%v, want a positive backoff for a deadline exceeded error", retry, wait)
+		}
+	})
+
+	t.Run("backoff never exceeds Max", func(t *testing.T) {
+		b := &ExponentialBackoff{Base: time.Second, Max: 2 * time.Second, Jitter: true}
+		for i := 0; i < 10; i++ {
+			_, wait := b.ShouldRetry(i, serverErr(http.StatusServiceUnavailable))
+			if wait > 2*time.Second {
+				t.Fatalf("attempt %d: wait %v exceeds Max %v", i, wait, b.Max)
+			}
+		}
+	})
+
+	t.Run("non-jitter backoff doubles each attempt up to Max", func(t *testing.T) {
+		b := &ExponentialBackoff{Base: time.Second, Max: 10 * time.Second}
+		want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+		for i, w := range want {
+			_, wait := b.ShouldRetry(i, serverErr(http.StatusServiceUnavailable))
+			if wait != w {
+				t.Errorf("attempt %d: wait = %v, want %v", i, wait, w)
+			}
+		}
+	})
+}
+
+func TestTokenBucketPolicy(t *testing.T) {
+	t.Run("RecordHeaders then Pace reflects the server's rate limit", func(t *testing.T) {
+		tb := NewTokenBucketPolicy(&ExponentialBackoff{Base: time.Second, Max: time.Minute})
+
+		header := http.Header{}
+		header.Set("x-ratelimit-remaining-requests", "0")
+		header.Set("x-ratelimit-reset-requests", "5s")
+		tb.RecordHeaders(header)
+
+		d := tb.Pace()
+		if d <= 0 || d > 5*time.Second {
+			t.Errorf("Pace() = %v, want a positive duration up to 5s", d)
+		}
+	})
+
+	t.Run("Pace is zero when requests remain", func(t *testing.T) {
+		tb := NewTokenBucketPolicy(nil)
+
+		header := http.Header{}
+		header.Set("x-ratelimit-remaining-requests", "10")
+		header.Set("x-ratelimit-reset-requests", "5s")
+		tb.RecordHeaders(header)
+
+		if d := tb.Pace(); d != 0 {
+			t.Errorf("Pace() = %v, want 0 while requests remain", d)
+		}
+	})
+
+	t.Run("Pace is zero before any headers are recorded", func(t *testing.T) {
+		tb := NewTokenBucketPolicy(nil)
+		if d := tb.Pace(); d != 0 {
+			t.Errorf("Pace() = %v, want 0 with no recorded state", d)
+		}
+	})
+
+	t.Run("ShouldRetry delegates to the fallback policy", func(t *testing.T) {
+		fallback := &ExponentialBackoff{Base: time.Second, Max: time.Minute}
+		tb := NewTokenBucketPolicy(fallback)
+
+		retry, _ := tb.ShouldRetry(0, serverErr(http.StatusServiceUnavailable))
+		if !retry {
+			t.Error("expected ShouldRetry to delegate a retryable 503 to the fallback")
+		}
+	})
+}
+
+func TestPace(t *testing.T) {
+	t.Run("a policy without a Pacer never blocks", func(t *testing.T) {
+		if err := pace(context.Background(), &ExponentialBackoff{}); err != nil {
+			t.Errorf("pace() = %v, want nil for a non-Pacer policy", err)
+		}
+	})
+
+	t.Run("returns immediately when Pace() reports no wait", func(t *testing.T) {
+		tb := NewTokenBucketPolicy(nil)
+		if err := pace(context.Background(), tb); err != nil {
+			t.Errorf("pace() = %v, want nil when Pace() is 0", err)
+		}
+	})
+
+	t.Run("a cancelled context aborts the wait instead of sleeping it out", func(t *testing.T) {
+		tb := NewTokenBucketPolicy(nil)
+		header := http.Header{}
+		header.Set("x-ratelimit-remaining-requests", "0")
+		header.Set("x-ratelimit-reset-requests", "1h")
+		tb.RecordHeaders(header)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := pace(ctx, tb); !errors.Is(err, context.Canceled) {
+			t.Errorf("pace() = %v, want context.Canceled", err)
+		}
+	})
+}